@@ -0,0 +1,253 @@
+// Package config loads a bindings file that maps each control, addressed
+// over either MIDI or OSC, onto one of the ctrl package's constructors, and
+// builds the resulting ctrl.Set. NewBuilder's result satisfies
+// reload.Builder, so a bindings file can be handed straight to
+// reload.NewUpdater for hot reload.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ftl/tci/client"
+
+	"github.com/ok1cdj/midi2tci/pkg/ctrl"
+	"github.com/ok1cdj/midi2tci/pkg/osc"
+	"github.com/ok1cdj/midi2tci/pkg/reload"
+)
+
+// File is the top-level shape of a bindings file: a flat list of controls,
+// each addressed over MIDI or OSC and bound to one of ctrl's constructors.
+type File struct {
+	Bindings []Binding `json:"bindings"`
+}
+
+// Binding is one entry in a bindings file.
+type Binding struct {
+	// Transport selects how this binding is addressed: "midi" (the
+	// default) or "osc".
+	Transport string `json:"transport,omitempty"`
+
+	// Channel and Key address a MIDI binding; used when Transport is
+	// "midi" or empty.
+	Channel byte `json:"channel,omitempty"`
+	Key     byte `json:"key,omitempty"`
+
+	// Address is the OSC address, e.g. "/trx/0/mute"; used when Transport
+	// is "osc".
+	Address string `json:"address,omitempty"`
+
+	// Control selects which ctrl constructor this binding builds. See
+	// build for the supported kinds.
+	Control string `json:"control"`
+
+	TRX       int    `json:"trx,omitempty"`
+	VFO       string `json:"vfo,omitempty"`
+	Translate string `json:"translate,omitempty"`
+
+	// RateLimit rate-limits this control's inbound/outbound updates, e.g.
+	// "50ms". Empty means no rate limit.
+	RateLimit string `json:"rate_limit,omitempty"`
+}
+
+// load reads and parses a bindings file from path.
+func load(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, err
+	}
+	var file File
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return File{}, fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// id resolves the binding's transport-specific address into a
+// ctrl.ControlID, the same way regardless of which transport it arrives
+// over.
+func (b Binding) id() (ctrl.ControlID, error) {
+	switch b.Transport {
+	case "", "midi":
+		return ctrl.MidiKey{Channel: b.Channel, Key: b.Key}, nil
+	case "osc":
+		if b.Address == "" {
+			return nil, fmt.Errorf("osc binding is missing an address")
+		}
+		return osc.Address(b.Address), nil
+	default:
+		return nil, fmt.Errorf("unknown transport %q", b.Transport)
+	}
+}
+
+func (b Binding) rateLimit() (time.Duration, error) {
+	if b.RateLimit == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(b.RateLimit)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate_limit %q: %w", b.RateLimit, err)
+	}
+	return d, nil
+}
+
+func (b Binding) vfo() (client.VFO, error) {
+	switch b.VFO {
+	case "", "A":
+		return client.VFOA, nil
+	case "B":
+		return client.VFOB, nil
+	default:
+		return 0, fmt.Errorf("unknown vfo %q", b.VFO)
+	}
+}
+
+// handler is the uniform shape every supported control kind is reduced to:
+// a button ignores value and fires on any call, a fader applies value
+// directly.
+type handler func(value int)
+
+// Bindings is one generation of controllers built from a bindings file. It
+// satisfies reload.Set, so it can be handed straight to reload.NewUpdater
+// via NewBuilder.
+type Bindings struct {
+	set  ctrl.Set
+	midi map[ctrl.MidiKey]handler
+}
+
+// Close releases every controller in the set that owns a goroutine.
+func (b *Bindings) Close() {
+	b.set.Close()
+}
+
+// Dispatch delivers value to the controller bound to key, e.g. from a MIDI
+// input driver. It reports whether anything in this generation was bound to
+// key.
+func (b *Bindings) Dispatch(key ctrl.MidiKey, value int) bool {
+	h, ok := b.midi[key]
+	if !ok {
+		return false
+	}
+	h(value)
+	return true
+}
+
+// NewBuilder returns a reload.Builder that loads a bindings file and
+// constructs the ctrl.Set it describes against tci. OSC bindings register
+// their handler on surface and use it as their ctrl.Feedback; MIDI bindings
+// use led as their ctrl.Feedback and are returned through the built
+// Bindings' Dispatch method for an external MIDI driver to call. surface
+// may be nil if the config only uses MIDI bindings.
+func NewBuilder(tci *client.Client, led ctrl.Feedback, surface *osc.Surface) reload.Builder {
+	return func(path string) (reload.Set, error) {
+		file, err := load(path)
+		if err != nil {
+			return nil, err
+		}
+		return build(file, tci, led, surface)
+	}
+}
+
+func build(file File, tci *client.Client, led ctrl.Feedback, surface *osc.Surface) (*Bindings, error) {
+	result := &Bindings{midi: make(map[ctrl.MidiKey]handler)}
+
+	for _, b := range file.Bindings {
+		id, err := b.id()
+		if err != nil {
+			return nil, fmt.Errorf("binding %q: %w", b.Control, err)
+		}
+
+		feedback := led
+		if _, ok := id.(osc.Address); ok {
+			if surface == nil {
+				return nil, fmt.Errorf("binding %q: osc binding needs a surface", b.Control)
+			}
+			feedback = surface
+		}
+
+		h, closer, err := b.build(id, feedback, tci)
+		if err != nil {
+			return nil, fmt.Errorf("binding %q: %w", b.Control, err)
+		}
+		if closer != nil {
+			result.set = append(result.set, closer)
+		}
+
+		switch addr := id.(type) {
+		case osc.Address:
+			surface.Handle(addr, func(args []interface{}) { h(oscValue(args)) })
+		case ctrl.MidiKey:
+			result.midi[addr] = h
+		}
+	}
+
+	return result, nil
+}
+
+// build constructs the controller for one binding and reduces it to the
+// uniform handler shape, along with its ctrl.Closer if it owns a goroutine.
+// The supported control kinds are "mute", "rx_enable", "split_enable" and
+// "volume".
+func (b Binding) build(id ctrl.ControlID, feedback ctrl.Feedback, tci *client.Client) (handler, ctrl.Closer, error) {
+	rateLimit, err := b.rateLimit()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch b.Control {
+	case "mute":
+		button := ctrl.NewMuteButton(id, feedback, tci, rateLimit)
+		return func(int) { button.Pressed() }, nil, nil
+
+	case "rx_enable":
+		vfo, err := b.vfo()
+		if err != nil {
+			return nil, nil, err
+		}
+		button := ctrl.NewRXChannelEnableButton(id, b.TRX, vfo, feedback, tci, rateLimit)
+		return func(int) { button.Pressed() }, nil, nil
+
+	case "split_enable":
+		button := ctrl.NewSplitEnableButton(id, b.TRX, feedback, tci, rateLimit)
+		return func(int) { button.Pressed() }, nil, nil
+
+	case "volume":
+		var slider *ctrl.VolumeSlider
+		if b.Translate != "" {
+			slider, err = ctrl.NewExprVolumeSlider(tci, b.Translate, rateLimit)
+			if err != nil {
+				return nil, nil, err
+			}
+		} else {
+			slider = ctrl.NewVolumeSlider(tci, rateLimit)
+		}
+		return func(v int) { slider.Changed(v) }, slider, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown control %q", b.Control)
+	}
+}
+
+// oscValue extracts the first numeric argument of an incoming OSC message
+// as a raw controller value, expected in the same 0-127 range as a MIDI CC,
+// so bindings behave identically regardless of transport. Messages with no
+// numeric argument (e.g. a bare button press) yield 0, which button
+// handlers ignore.
+func oscValue(args []interface{}) int {
+	if len(args) == 0 {
+		return 0
+	}
+	switch v := args[0].(type) {
+	case float32:
+		return int(v)
+	case float64:
+		return int(v)
+	case int32:
+		return int(v)
+	default:
+		return 0
+	}
+}
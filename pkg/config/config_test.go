@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ok1cdj/midi2tci/pkg/ctrl"
+	"github.com/ok1cdj/midi2tci/pkg/osc"
+)
+
+func TestLoadParsesBindingsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bindings.json")
+	const content = `{"bindings": [
+		{"control": "mute", "channel": 1, "key": 2},
+		{"transport": "osc", "address": "/trx/0/mute", "control": "mute"}
+	]}`
+	err := os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(file.Bindings) != 2 {
+		t.Fatalf("expected 2 bindings, got %d", len(file.Bindings))
+	}
+	if file.Bindings[0].Control != "mute" || file.Bindings[0].Key != 2 {
+		t.Fatalf("unexpected first binding: %+v", file.Bindings[0])
+	}
+	if file.Bindings[1].Transport != "osc" || file.Bindings[1].Address != "/trx/0/mute" {
+		t.Fatalf("unexpected second binding: %+v", file.Bindings[1])
+	}
+}
+
+func TestBindingIDResolvesTransport(t *testing.T) {
+	midiBinding := Binding{Control: "mute", Channel: 1, Key: 2}
+	id, err := midiBinding.id()
+	if err != nil {
+		t.Fatalf("midi id: %v", err)
+	}
+	if id != (ctrl.MidiKey{Channel: 1, Key: 2}) {
+		t.Fatalf("unexpected midi id: %#v", id)
+	}
+
+	oscBinding := Binding{Transport: "osc", Control: "mute", Address: "/trx/0/mute"}
+	id, err = oscBinding.id()
+	if err != nil {
+		t.Fatalf("osc id: %v", err)
+	}
+	if id != osc.Address("/trx/0/mute") {
+		t.Fatalf("unexpected osc id: %#v", id)
+	}
+
+	_, err = (Binding{Transport: "osc", Control: "mute"}).id()
+	if err == nil {
+		t.Fatal("expected an error for an osc binding with no address")
+	}
+
+	_, err = (Binding{Transport: "bogus", Control: "mute"}).id()
+	if err == nil {
+		t.Fatal("expected an error for an unknown transport")
+	}
+}
+
+func TestBuildWiresMidiAndOSCBindings(t *testing.T) {
+	surface, err := osc.NewSurface("127.0.0.1:0", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewSurface: %v", err)
+	}
+
+	file := File{Bindings: []Binding{
+		{Control: "mute", Channel: 1, Key: 2},
+		{Transport: "osc", Control: "mute", Address: "/trx/0/mute"},
+	}}
+
+	bindings, err := build(file, nil, nil, surface)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	// Inspecting the unexported midi map directly (rather than calling
+	// Dispatch) avoids actually invoking MuteButton.Pressed, which would
+	// reach through to the nil *client.Client test double.
+	if _, ok := bindings.midi[ctrl.MidiKey{Channel: 1, Key: 2}]; !ok {
+		t.Fatal("expected the midi binding to be registered")
+	}
+	if _, ok := bindings.midi[ctrl.MidiKey{Channel: 9, Key: 9}]; ok {
+		t.Fatal("expected an unbound midi key not to be registered")
+	}
+}
+
+func TestBuildRejectsUnknownControl(t *testing.T) {
+	file := File{Bindings: []Binding{{Control: "bogus", Channel: 1, Key: 2}}}
+	_, err := build(file, nil, nil, nil)
+	if err == nil {
+		t.Fatal("expected an error for an unknown control kind")
+	}
+}
+
+func TestBuildRejectsOSCBindingWithoutSurface(t *testing.T) {
+	file := File{Bindings: []Binding{{Transport: "osc", Control: "mute", Address: "/trx/0/mute"}}}
+	_, err := build(file, nil, nil, nil)
+	if err == nil {
+		t.Fatal("expected an error for an osc binding with no surface")
+	}
+}
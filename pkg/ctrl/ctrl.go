@@ -2,32 +2,54 @@ package ctrl
 
 import (
 	"log"
+	"sync/atomic"
 	"time"
 
 	"github.com/ftl/tci/client"
 )
 
+// ControlID identifies a single control binding independent of the transport
+// it arrives over. MidiKey is the original implementation; other transports
+// (for example OSC addresses, in another package) provide their own, which
+// is why the marker method is exported rather than a same-package-only
+// unexported one.
+type ControlID interface {
+	ControlID()
+}
+
 type MidiKey struct {
 	Channel byte
 	Key     byte
 }
 
-type LED interface {
-	Set(key MidiKey, on bool)
+func (MidiKey) ControlID() {}
+
+// Feedback turns a controller's current value into an outbound indication on
+// whatever surface ControlID belongs to, e.g. lighting a MIDI LED or updating
+// an OSC-driven widget.
+type Feedback interface {
+	Set(id ControlID, on bool)
 }
 
-func NewMuteButton(key MidiKey, led LED, muter Muter) *MuteButton {
+// NewMuteButton creates a mute button whose inbound and outbound updates are
+// no closer together than rateLimit, protecting TCI from a jittery control
+// and letting the button tell its own echo apart from a genuine external
+// change.
+func NewMuteButton(key ControlID, led Feedback, muter Muter, rateLimit time.Duration) *MuteButton {
 	return &MuteButton{
 		key:   key,
 		led:   led,
 		muter: muter,
+		state: NewTimestamped(rateLimit),
 	}
 }
 
 type MuteButton struct {
-	key   MidiKey
-	led   LED
-	muter Muter
+	key      ControlID
+	led      Feedback
+	muter    Muter
+	state    Timestamped
+	notifier Notifier
 
 	muted bool
 }
@@ -37,33 +59,95 @@ type Muter interface {
 }
 
 func (b *MuteButton) Pressed() {
+	if !b.state.AllowSend() {
+		return
+	}
 	err := b.muter.SetMute(!b.muted)
 	if err != nil {
 		log.Print(err)
 	}
 }
 
+// SetNotifier attaches a Notifier that is told about every genuine mute
+// state change, e.g. for an audio or desktop cue. A nil notifier (the
+// default) means no events are pushed.
+func (b *MuteButton) SetNotifier(notifier Notifier) {
+	b.notifier = notifier
+}
+
 func (b *MuteButton) SetMute(muted bool) {
+	echo := b.state.ReceiveUpdate()
+	changed := muted != b.muted
+	if echo && !changed {
+		return
+	}
 	b.muted = muted
 	b.led.Set(b.key, !muted)
+	if changed && b.notifier != nil {
+		if muted {
+			b.notifier.Notify("mute on")
+		} else {
+			b.notifier.Notify("mute off")
+		}
+	}
+}
+
+// MuteButtonState is a point-in-time snapshot of a MuteButton, suitable for
+// a debug/status endpoint.
+type MuteButtonState struct {
+	Muted bool
+	TimestampState
+}
+
+func (b *MuteButton) State() MuteButtonState {
+	return MuteButtonState{Muted: b.muted, TimestampState: b.state.Snapshot()}
+}
+
+// NewExprButton creates a button whose press runs a compiled ActionProgram
+// instead of calling a single domain interface, so a config file can compose
+// arbitrary multi-step macros (e.g. toggling a mute and mirroring it onto
+// another channel's split). env is called on every press to get the current
+// TCI state snapshot the program runs against.
+func NewExprButton(key ControlID, program *ActionProgram, env func() interface{}) *ExprButton {
+	return &ExprButton{
+		key:     key,
+		program: program,
+		env:     env,
+	}
+}
+
+type ExprButton struct {
+	key     ControlID
+	program *ActionProgram
+	env     func() interface{}
 }
 
-func NewRXChannelEnableButton(key MidiKey, trx int, vfo client.VFO, led LED, rxChannelEnabler RXChannelEnabler) *RXChannelEnableButton {
+func (b *ExprButton) Pressed() {
+	err := b.program.Run(b.env())
+	if err != nil {
+		log.Print(err)
+	}
+}
+
+func NewRXChannelEnableButton(key ControlID, trx int, vfo client.VFO, led Feedback, rxChannelEnabler RXChannelEnabler, rateLimit time.Duration) *RXChannelEnableButton {
 	return &RXChannelEnableButton{
 		key:              key,
 		trx:              trx,
 		vfo:              vfo,
 		led:              led,
 		rxChannelEnabler: rxChannelEnabler,
+		state:            NewTimestamped(rateLimit),
 	}
 }
 
 type RXChannelEnableButton struct {
-	key              MidiKey
+	key              ControlID
 	trx              int
 	vfo              client.VFO
-	led              LED
+	led              Feedback
 	rxChannelEnabler RXChannelEnabler
+	state            Timestamped
+	notifier         Notifier
 
 	enabled bool
 }
@@ -73,34 +157,70 @@ type RXChannelEnabler interface {
 }
 
 func (b *RXChannelEnableButton) Pressed() {
+	if !b.state.AllowSend() {
+		return
+	}
 	err := b.rxChannelEnabler.SetRXChannelEnable(b.trx, b.vfo, !b.enabled)
 	if err != nil {
 		log.Print(err)
 	}
 }
 
+// SetNotifier attaches a Notifier that is told about every genuine RX
+// channel enable/disable. A nil notifier (the default) means no events are
+// pushed.
+func (b *RXChannelEnableButton) SetNotifier(notifier Notifier) {
+	b.notifier = notifier
+}
+
 func (b *RXChannelEnableButton) SetRXChannelEnable(trx int, vfo client.VFO, enabled bool) {
 	if trx != b.trx || vfo != b.vfo {
 		return
 	}
+	echo := b.state.ReceiveUpdate()
+	changed := enabled != b.enabled
+	if echo && !changed {
+		return
+	}
 	b.enabled = enabled
 	b.led.Set(b.key, enabled)
+	if changed && b.notifier != nil {
+		if enabled {
+			b.notifier.Notify("RX channel enabled")
+		} else {
+			b.notifier.Notify("RX channel disabled")
+		}
+	}
+}
+
+// RXChannelEnableButtonState is a point-in-time snapshot of an
+// RXChannelEnableButton, suitable for a debug/status endpoint.
+type RXChannelEnableButtonState struct {
+	Enabled bool
+	TimestampState
 }
 
-func NewSplitEnableButton(key MidiKey, trx int, led LED, splitEnabler SplitEnabler) *SplitEnableButton {
+func (b *RXChannelEnableButton) State() RXChannelEnableButtonState {
+	return RXChannelEnableButtonState{Enabled: b.enabled, TimestampState: b.state.Snapshot()}
+}
+
+func NewSplitEnableButton(key ControlID, trx int, led Feedback, splitEnabler SplitEnabler, rateLimit time.Duration) *SplitEnableButton {
 	return &SplitEnableButton{
 		key:          key,
 		trx:          trx,
 		led:          led,
 		splitEnabler: splitEnabler,
+		state:        NewTimestamped(rateLimit),
 	}
 }
 
 type SplitEnableButton struct {
-	key          MidiKey
+	key          ControlID
 	trx          int
-	led          LED
+	led          Feedback
 	splitEnabler SplitEnabler
+	state        Timestamped
+	notifier     Notifier
 
 	enabled bool
 }
@@ -110,26 +230,59 @@ type SplitEnabler interface {
 }
 
 func (b *SplitEnableButton) Pressed() {
+	if !b.state.AllowSend() {
+		return
+	}
 	err := b.splitEnabler.SetSplitEnable(b.trx, !b.enabled)
 	if err != nil {
 		log.Print(err)
 	}
 }
 
+// SetNotifier attaches a Notifier that is told about every genuine split
+// enable/disable. A nil notifier (the default) means no events are pushed.
+func (b *SplitEnableButton) SetNotifier(notifier Notifier) {
+	b.notifier = notifier
+}
+
 func (b *SplitEnableButton) SetSplitEnable(trx int, enabled bool) {
 	if trx != b.trx {
 		return
 	}
+	echo := b.state.ReceiveUpdate()
+	changed := enabled != b.enabled
+	if echo && !changed {
+		return
+	}
 	b.enabled = enabled
 	b.led.Set(b.key, enabled)
+	if changed && b.notifier != nil {
+		if enabled {
+			b.notifier.Notify("split enabled")
+		} else {
+			b.notifier.Notify("split disabled")
+		}
+	}
 }
 
-func NewVFOWheel(key MidiKey, trx int, vfo client.VFO, controller VFOFrequencyController) *VFOWheel {
+// SplitEnableButtonState is a point-in-time snapshot of a
+// SplitEnableButton, suitable for a debug/status endpoint.
+type SplitEnableButtonState struct {
+	Enabled bool
+	TimestampState
+}
+
+func (b *SplitEnableButton) State() SplitEnableButtonState {
+	return SplitEnableButtonState{Enabled: b.enabled, TimestampState: b.state.Snapshot()}
+}
+
+func NewVFOWheel(key ControlID, trx int, vfo client.VFO, controller VFOFrequencyController, rateLimit time.Duration) *VFOWheel {
 	result := &VFOWheel{
 		key:        key,
 		trx:        trx,
 		vfo:        vfo,
 		controller: controller,
+		state:      NewTimestamped(rateLimit),
 		frequency:  make(chan int, 1000),
 		turns:      make(chan int, 1000),
 		closed:     make(chan struct{}),
@@ -152,17 +305,23 @@ func NewVFOWheel(key MidiKey, trx int, vfo client.VFO, controller VFOFrequencyCo
 				accumulatedTurns += turns
 				turning = frequency > 0
 			case f := <-result.frequency:
-				if !turning {
+				echo := result.state.ReceiveUpdate()
+				if !turning && !(echo && f == frequency) {
 					frequency = f
+					atomic.StoreInt64(&result.currentFrequency, int64(frequency))
 				}
 			case <-ticker.C:
 				if accumulatedTurns == 0 {
 					turning = false
-				} else if accumulatedTurns != 0 && frequency != 0 {
+				} else if frequency != 0 {
+					if !result.state.AllowSend() {
+						continue
+					}
 					frequency = frequency + int(float64(accumulatedTurns)*1.8)
+					atomic.StoreInt64(&result.currentFrequency, int64(frequency))
 					err := result.controller.SetVFOFrequency(result.trx, result.vfo, frequency)
 					if err != nil {
-						log.Printf("Cannot change frequency to %d: %v", result.frequency, err)
+						log.Printf("Cannot change frequency to %d: %v", frequency, err)
 					}
 					accumulatedTurns = 0
 				}
@@ -174,14 +333,17 @@ func NewVFOWheel(key MidiKey, trx int, vfo client.VFO, controller VFOFrequencyCo
 }
 
 type VFOWheel struct {
-	key        MidiKey
+	key        ControlID
 	trx        int
 	vfo        client.VFO
 	controller VFOFrequencyController
+	state      Timestamped
 
 	frequency chan int
 	turns     chan int
 	closed    chan struct{}
+
+	currentFrequency int64
 }
 
 type VFOFrequencyController interface {
@@ -209,10 +371,25 @@ func (w *VFOWheel) SetVFOFrequency(trx int, vfo client.VFO, frequency int) {
 	w.frequency <- frequency
 }
 
-func NewSlider(set func(int), translate func(int) int) *Slider {
+// VFOWheelState is a point-in-time snapshot of a VFOWheel, suitable for a
+// debug/status endpoint.
+type VFOWheelState struct {
+	Frequency int
+	TimestampState
+}
+
+func (w *VFOWheel) State() VFOWheelState {
+	return VFOWheelState{
+		Frequency:      int(atomic.LoadInt64(&w.currentFrequency)),
+		TimestampState: w.state.Snapshot(),
+	}
+}
+
+func NewSlider(set func(int), translate Translator, rateLimit time.Duration) *Slider {
 	result := &Slider{
 		set:           set,
 		translate:     translate,
+		state:         NewTimestamped(rateLimit),
 		selectedValue: make(chan int, 1000),
 		activeValue:   make(chan int, 1000),
 		closed:        make(chan struct{}),
@@ -225,10 +402,13 @@ func NewSlider(set func(int), translate func(int) int) *Slider {
 
 type Slider struct {
 	set           func(int)
-	translate     func(int) int
+	translate     Translator
+	state         Timestamped
 	activeValue   chan int
 	selectedValue chan int
 	closed        chan struct{}
+
+	currentActive int64
 }
 
 func (s *Slider) start() {
@@ -259,8 +439,10 @@ func (s *Slider) start() {
 				if !valid {
 					return
 				}
+				echo := s.state.ReceiveUpdate()
 				activeValue = value
-				if !pending {
+				atomic.StoreInt64(&s.currentActive, int64(activeValue))
+				if !pending && !(echo && activeValue == selectedValue) {
 					selectedValue = activeValue
 				}
 			case value, valid := <-s.selectedValue:
@@ -272,6 +454,10 @@ func (s *Slider) start() {
 				if activeValue == selectedValue {
 					continue
 				}
+				if !s.state.AllowSend() {
+					pending = true
+					continue
+				}
 
 				select {
 				case tx <- selectedValue:
@@ -284,6 +470,9 @@ func (s *Slider) start() {
 					pending = false
 					continue
 				}
+				if !s.state.AllowSend() {
+					continue
+				}
 
 				select {
 				case tx <- selectedValue:
@@ -308,10 +497,24 @@ func (s *Slider) Close() {
 }
 
 func (s *Slider) Changed(value int) {
-	s.selectedValue <- s.translate(value)
+	s.selectedValue <- s.translate.Translate(value)
+}
+
+// SliderState is a point-in-time snapshot of a Slider, suitable for a
+// debug/status endpoint.
+type SliderState struct {
+	ActiveValue int
+	TimestampState
+}
+
+func (s *Slider) State() SliderState {
+	return SliderState{
+		ActiveValue:    int(atomic.LoadInt64(&s.currentActive)),
+		TimestampState: s.state.Snapshot(),
+	}
 }
 
-func NewVolumeSlider(controller VolumeController) *VolumeSlider {
+func NewVolumeSlider(controller VolumeController, rateLimit time.Duration) *VolumeSlider {
 	const tick = float64(60.0 / 127.0)
 	return &VolumeSlider{
 		Slider: NewSlider(
@@ -321,11 +524,34 @@ func NewVolumeSlider(controller VolumeController) *VolumeSlider {
 					log.Printf("Cannot change volume: %v", err)
 				}
 			},
-			func(v int) int { return -60 + int(float64(v)*tick) },
+			translatorFunc(func(v int) int { return -60 + int(float64(v)*tick) }),
+			rateLimit,
 		),
 	}
 }
 
+// NewExprVolumeSlider is like NewVolumeSlider, but the dB curve is given as
+// an expression instead of the built-in linear taper, e.g.
+// "pow(v/127.0, 2.2) * 100" for an audio taper.
+func NewExprVolumeSlider(controller VolumeController, translate string, rateLimit time.Duration) (*VolumeSlider, error) {
+	translator, err := NewExprTranslator(translate)
+	if err != nil {
+		return nil, err
+	}
+	return &VolumeSlider{
+		Slider: NewSlider(
+			func(v int) {
+				err := controller.SetVolume(v)
+				if err != nil {
+					log.Printf("Cannot change volume: %v", err)
+				}
+			},
+			translator,
+			rateLimit,
+		),
+	}, nil
+}
+
 type VolumeSlider struct {
 	*Slider
 }
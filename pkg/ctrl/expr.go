@@ -0,0 +1,150 @@
+package ctrl
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// Translator maps a raw controller value (the MIDI/OSC native range, usually
+// 0-127) to the value a controller actually applies, e.g. dB, Hz or percent.
+type Translator interface {
+	Translate(v int) int
+}
+
+// translatorFunc adapts a plain Go function to Translator, for the built-in
+// curves that don't need the expression language.
+type translatorFunc func(int) int
+
+func (f translatorFunc) Translate(v int) int { return f(v) }
+
+// ExprTranslator evaluates a user-supplied expression (see
+// github.com/antonmedv/expr) to translate a raw controller value, e.g.
+// "-60 + v * (60/127)" or "pow(v/127.0, 2.2) * 100" for an audio taper. The
+// expression is compiled once, at construction, so Translate only pays for
+// evaluating the compiled program.
+type ExprTranslator struct {
+	program *vm.Program
+}
+
+// NewExprTranslator compiles expression against an environment where v is
+// the raw controller value.
+func NewExprTranslator(expression string) (*ExprTranslator, error) {
+	program, err := expr.Compile(expression, exprOptions(nil)...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compile translation %q: %w", expression, err)
+	}
+	return &ExprTranslator{program: program}, nil
+}
+
+func (t *ExprTranslator) Translate(v int) int {
+	output, err := expr.Run(t.program, map[string]interface{}{"v": v})
+	if err != nil {
+		log.Printf("Cannot evaluate translation: %v", err)
+		return v
+	}
+	result, ok := toInt(output)
+	if !ok {
+		log.Printf("Translation returned non-numeric value %v", output)
+		return v
+	}
+	return result
+}
+
+// ActionProgram is one or more semicolon-separated expressions, compiled
+// once at construction and evaluated in order against the current TCI state
+// whenever a button bound to it is pressed, e.g.
+// "trx0.setMute(!trx0.mute()); trx1.setSplit(trx0.mute())". The environment
+// that resolves trx0/trx1 is supplied by the caller, both at compile time
+// (for type checking) and at Run time (for the actual state).
+type ActionProgram struct {
+	programs []*vm.Program
+}
+
+// NewActionProgram compiles expression against env, which should expose
+// whatever variables/functions the expression is allowed to reference.
+func NewActionProgram(expression string, env interface{}) (*ActionProgram, error) {
+	statements := strings.Split(expression, ";")
+	programs := make([]*vm.Program, 0, len(statements))
+	for _, stmt := range statements {
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" {
+			continue
+		}
+		program, err := expr.Compile(stmt, exprOptions(env)...)
+		if err != nil {
+			return nil, fmt.Errorf("cannot compile action %q: %w", stmt, err)
+		}
+		programs = append(programs, program)
+	}
+	return &ActionProgram{programs: programs}, nil
+}
+
+// Run evaluates each compiled statement against env in order. A statement's
+// side effects on env are what actually changes state; its return value is
+// discarded.
+func (p *ActionProgram) Run(env interface{}) error {
+	for _, program := range p.programs {
+		_, err := expr.Run(program, env)
+		if err != nil {
+			return fmt.Errorf("cannot run action: %w", err)
+		}
+	}
+	return nil
+}
+
+// exprOptions returns the expr.Option set shared by every compiled
+// translation and action, so they all see the same built-in functions. env
+// may be nil, in which case only the raw value v is in scope.
+func exprOptions(env interface{}) []expr.Option {
+	if env == nil {
+		env = map[string]interface{}{"v": 0}
+	}
+	return []expr.Option{
+		expr.Env(env),
+		expr.Function("pow", func(params ...interface{}) (interface{}, error) {
+			if len(params) != 2 {
+				return nil, fmt.Errorf("pow expects 2 arguments, got %d", len(params))
+			}
+			base, ok := toFloat(params[0])
+			if !ok {
+				return nil, fmt.Errorf("pow: %v is not numeric", params[0])
+			}
+			exponent, ok := toFloat(params[1])
+			if !ok {
+				return nil, fmt.Errorf("pow: %v is not numeric", params[1])
+			}
+			return math.Pow(base, exponent), nil
+		}),
+	}
+}
+
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	case float32:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,90 @@
+package ctrl
+
+import "testing"
+
+func TestExprTranslatorEvaluatesExpression(t *testing.T) {
+	translator, err := NewExprTranslator("-60 + v * (60.0/127.0)")
+	if err != nil {
+		t.Fatalf("NewExprTranslator: %v", err)
+	}
+
+	if got := translator.Translate(0); got != -60 {
+		t.Fatalf("Translate(0) = %d, want -60", got)
+	}
+	if got := translator.Translate(127); got != 0 {
+		t.Fatalf("Translate(127) = %d, want 0", got)
+	}
+}
+
+func TestExprTranslatorUsesThePowFunction(t *testing.T) {
+	translator, err := NewExprTranslator("pow(v, 2)")
+	if err != nil {
+		t.Fatalf("NewExprTranslator: %v", err)
+	}
+
+	if got := translator.Translate(4); got != 16 {
+		t.Fatalf("Translate(4) = %d, want 16", got)
+	}
+}
+
+func TestExprTranslatorRejectsInvalidExpression(t *testing.T) {
+	_, err := NewExprTranslator("v +")
+	if err == nil {
+		t.Fatal("expected an error compiling an invalid expression")
+	}
+}
+
+func TestExprTranslatorFallsBackToInputOnRuntimeError(t *testing.T) {
+	translator, err := NewExprTranslator("pow(v, \"not a number\")")
+	if err != nil {
+		t.Fatalf("NewExprTranslator: %v", err)
+	}
+
+	if got := translator.Translate(42); got != 42 {
+		t.Fatalf("Translate(42) = %d, want 42 (the input, on evaluation error)", got)
+	}
+}
+
+type actionEnv struct {
+	mute  bool
+	split bool
+}
+
+func (e *actionEnv) Mute() bool { return e.mute }
+
+func (e *actionEnv) SetMute(v bool) bool {
+	e.mute = v
+	return v
+}
+
+func (e *actionEnv) SetSplit(v bool) bool {
+	e.split = v
+	return v
+}
+
+func TestActionProgramRunsEachStatementInOrder(t *testing.T) {
+	env := &actionEnv{}
+	program, err := NewActionProgram("SetMute(!Mute()); SetSplit(Mute())", env)
+	if err != nil {
+		t.Fatalf("NewActionProgram: %v", err)
+	}
+
+	err = program.Run(env)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if !env.mute {
+		t.Fatal("expected Mute to have been toggled on")
+	}
+	if !env.split {
+		t.Fatal("expected Split to mirror the new Mute state")
+	}
+}
+
+func TestActionProgramRejectsInvalidStatement(t *testing.T) {
+	_, err := NewActionProgram("NoSuchMethod()", &actionEnv{})
+	if err == nil {
+		t.Fatal("expected an error compiling an action referencing an unknown method")
+	}
+}
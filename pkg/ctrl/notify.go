@@ -0,0 +1,11 @@
+package ctrl
+
+// Notifier receives high-level, human-meaningful events that a controller
+// pushes when its state actually changes, as opposed to Feedback, which just
+// mirrors a raw value back onto a surface, e.g. "mute on" or "RX B enabled".
+// Event listeners outside this package (e.g. an SWR threshold monitor or a
+// spot watcher) can implement the same interface to reuse the same
+// desktop/audio backends.
+type Notifier interface {
+	Notify(event string)
+}
@@ -0,0 +1,21 @@
+package ctrl
+
+// Closer is implemented by controllers that own a background goroutine
+// (VFOWheel, Slider) and must release it once they are no longer bound to
+// anything, e.g. when a config hot-reload replaces them.
+type Closer interface {
+	Close()
+}
+
+// Set is one generation of controllers built from a config file. Closing it
+// releases every controller in it that owns a goroutine; controllers that
+// don't (MuteButton, RXChannelEnableButton, SplitEnableButton, ExprButton)
+// are simply dropped.
+type Set []Closer
+
+// Close releases every Closer in the set.
+func (s Set) Close() {
+	for _, c := range s {
+		c.Close()
+	}
+}
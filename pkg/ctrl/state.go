@@ -0,0 +1,69 @@
+package ctrl
+
+import (
+	"sync"
+	"time"
+)
+
+// Timestamped tracks when a piece of controller state last changed on each
+// side of the link: an update received from TCI, and a value we wrote out
+// ourselves. Controllers use it to recognize their own echo and to
+// rate-limit outbound writes, the same *Updated time.Time bookkeeping
+// rhctl's SwitchState uses for its switches, made uniform across every
+// controller in this package instead of ad hoc flags like VFOWheel's old
+// turning bool.
+type Timestamped struct {
+	window time.Duration
+
+	mu       sync.Mutex
+	received time.Time
+	sent     time.Time
+}
+
+// NewTimestamped creates the bookkeeping for one piece of controller state.
+// window both rate-limits outbound writes (AllowSend won't allow two within
+// window) and bounds how long after a send an inbound value is assumed to be
+// our own echo rather than a genuine external change (ReceiveUpdate). A zero
+// window disables both.
+func NewTimestamped(window time.Duration) Timestamped {
+	return Timestamped{window: window}
+}
+
+// ReceiveUpdate records that a value arrived from TCI's side and reports
+// whether it looks like the echo of a value we sent ourselves within window.
+func (t *Timestamped) ReceiveUpdate() (echo bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	echo = t.window > 0 && !t.sent.IsZero() && now.Sub(t.sent) < t.window
+	t.received = now
+	return echo
+}
+
+// AllowSend reports whether window has elapsed since the last outbound
+// write. If so, it records the send and returns true; otherwise the caller
+// should drop the write, protecting TCI from a jittery encoder.
+func (t *Timestamped) AllowSend() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if t.window > 0 && !t.sent.IsZero() && now.Sub(t.sent) < t.window {
+		return false
+	}
+	t.sent = now
+	return true
+}
+
+// TimestampState is a point-in-time, copyable snapshot of a Timestamped,
+// safe to hand to a debug/status endpoint.
+type TimestampState struct {
+	Received time.Time
+	Sent     time.Time
+}
+
+// Snapshot returns the current TimestampState.
+func (t *Timestamped) Snapshot() TimestampState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return TimestampState{Received: t.received, Sent: t.sent}
+}
@@ -0,0 +1,74 @@
+package ctrl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimestampedZeroWindowDisablesRateLimitAndEcho(t *testing.T) {
+	state := NewTimestamped(0)
+
+	if !state.AllowSend() {
+		t.Fatal("first send should always be allowed")
+	}
+	if !state.AllowSend() {
+		t.Fatal("zero window should never rate-limit sends")
+	}
+	if state.ReceiveUpdate() {
+		t.Fatal("zero window should never report an echo")
+	}
+}
+
+func TestTimestampedAllowSendRateLimits(t *testing.T) {
+	state := NewTimestamped(50 * time.Millisecond)
+
+	if !state.AllowSend() {
+		t.Fatal("first send should always be allowed")
+	}
+	if state.AllowSend() {
+		t.Fatal("send within the window should be rate-limited")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if !state.AllowSend() {
+		t.Fatal("send after the window elapsed should be allowed")
+	}
+}
+
+func TestTimestampedReceiveUpdateDetectsEcho(t *testing.T) {
+	state := NewTimestamped(50 * time.Millisecond)
+
+	if !state.AllowSend() {
+		t.Fatal("first send should always be allowed")
+	}
+	if !state.ReceiveUpdate() {
+		t.Fatal("an update arriving right after our own send should be an echo")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if state.ReceiveUpdate() {
+		t.Fatal("an update arriving after the echo window should not be an echo")
+	}
+}
+
+func TestTimestampedSnapshot(t *testing.T) {
+	state := NewTimestamped(0)
+
+	empty := state.Snapshot()
+	if !empty.Received.IsZero() || !empty.Sent.IsZero() {
+		t.Fatalf("a fresh Timestamped should have a zero snapshot, got %+v", empty)
+	}
+
+	state.AllowSend()
+	state.ReceiveUpdate()
+
+	snapshot := state.Snapshot()
+	if snapshot.Sent.IsZero() {
+		t.Fatal("Snapshot should report the last send")
+	}
+	if snapshot.Received.IsZero() {
+		t.Fatal("Snapshot should report the last received update")
+	}
+}
@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/faiface/beep/wav"
+)
+
+//go:embed sounds
+var sounds embed.FS
+
+// Audio is a ctrl.Notifier that plays a short embedded sound clip for each
+// event it has a cue for. Events with no matching clip are ignored, so it's
+// safe to attach it to every controller without curating exactly which
+// events get a sound.
+//
+// Decoding a cue never needs cgo, but actually producing sound does (see
+// output in audio_play.go); build with -tags audio to hear cues, otherwise
+// Notify decodes and silently drops them.
+type Audio struct {
+	cues map[string][]byte
+}
+
+// NewAudio loads the embedded sound clips named by cues, which maps an event
+// (as passed to Notify, e.g. "mute on") to a WAV file name under sounds/.
+func NewAudio(cues map[string]string) (*Audio, error) {
+	result := &Audio{
+		cues: make(map[string][]byte, len(cues)),
+	}
+	for event, filename := range cues {
+		data, err := sounds.ReadFile("sounds/" + filename)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load sound cue %q for %q: %w", filename, event, err)
+		}
+		result.cues[event] = data
+	}
+	return result, nil
+}
+
+// Notify plays the cue bound to event, if any, on the system's default audio
+// output. It does not block the caller; playback happens in its own
+// goroutine.
+func (a *Audio) Notify(event string) {
+	data, ok := a.cues[event]
+	if !ok {
+		return
+	}
+	go a.play(event, data)
+}
+
+func (a *Audio) play(event string, data []byte) {
+	streamer, format, err := wav.Decode(io.NopCloser(bytes.NewReader(data)))
+	if err != nil {
+		log.Printf("Cannot decode sound cue for %q: %v", event, err)
+		return
+	}
+	defer streamer.Close()
+
+	output(streamer, format)
+}
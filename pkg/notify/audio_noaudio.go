@@ -0,0 +1,18 @@
+//go:build !audio
+
+package notify
+
+import (
+	"log"
+
+	"github.com/faiface/beep"
+)
+
+// output is the no-op fallback used when this package is built without
+// -tags audio, since the real backend (audio_play.go) pulls in cgo and
+// libasound2-dev on Linux via faiface/beep/speaker -> hajimehoshi/oto. That
+// keeps a plain `go build ./...` working without that system dependency;
+// rebuild with -tags audio to actually hear the cues.
+func output(_ beep.StreamSeekCloser, _ beep.Format) {
+	log.Print("Audio cues are disabled in this build; rebuild with -tags audio to hear them")
+}
@@ -0,0 +1,44 @@
+//go:build audio
+
+package notify
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// outputSampleRate is the single rate every cue is resampled to before
+// playback: speaker.Init can only be configured once, and beep does not
+// resample streams of differing rates on its own, so two cues recorded at
+// different rates (e.g. a synthesized chirp vs. a recorded phrase) would
+// otherwise play back at the wrong speed and pitch whichever one loses the
+// race to initialize the speaker.
+const outputSampleRate = beep.SampleRate(44100)
+
+var initSpeaker sync.Once
+
+// output plays streamer on the system's default audio output. It blocks
+// until playback finishes.
+func output(streamer beep.StreamSeekCloser, format beep.Format) {
+	var initErr error
+	initSpeaker.Do(func() {
+		initErr = speaker.Init(outputSampleRate, outputSampleRate.N(time.Second/10))
+	})
+	if initErr != nil {
+		log.Printf("Cannot initialize audio output: %v", initErr)
+		return
+	}
+
+	var source beep.Streamer = streamer
+	if format.SampleRate != outputSampleRate {
+		source = beep.Resample(4, format.SampleRate, outputSampleRate, streamer)
+	}
+
+	done := make(chan struct{})
+	speaker.Play(beep.Seq(source, beep.Callback(func() { close(done) })))
+	<-done
+}
@@ -0,0 +1,28 @@
+// Package notify provides concrete ctrl.Notifier backends: a desktop toast
+// notifier and an audio-cue notifier that plays short embedded sound clips.
+package notify
+
+import (
+	"log"
+
+	"github.com/gen2brain/beeep"
+)
+
+// Desktop is a ctrl.Notifier that shows a native desktop notification (toast)
+// for every event it is told about.
+type Desktop struct {
+	appName string
+}
+
+// NewDesktop creates a Desktop notifier that labels every notification with
+// appName.
+func NewDesktop(appName string) *Desktop {
+	return &Desktop{appName: appName}
+}
+
+func (d *Desktop) Notify(event string) {
+	err := beeep.Notify(d.appName, event, "")
+	if err != nil {
+		log.Printf("Cannot show desktop notification %q: %v", event, err)
+	}
+}
@@ -0,0 +1,124 @@
+// Package osc provides an OSC transport for the ctrl package, so the same
+// button/slider/wheel controllers that are normally bound to MIDI notes can
+// instead (or additionally) be driven from OSC-capable surfaces such as
+// TouchOSC or Open Stage Control.
+package osc
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+
+	goosc "github.com/hypebeast/go-osc/osc"
+
+	"github.com/ok1cdj/midi2tci/pkg/ctrl"
+)
+
+// Address is an OSC address pattern, e.g. "/trx/0/mute" or "/trx/0/vfo/a/freq".
+// It implements ctrl.ControlID so controllers can be bound to it exactly like
+// a ctrl.MidiKey.
+type Address string
+
+func (Address) ControlID() {} // satisfies ctrl.ControlID, mirrors ctrl.MidiKey
+
+var _ ctrl.ControlID = Address("")
+
+// Handler reacts to an incoming OSC message for an Address. args holds the
+// message's arguments as sent by the surface, typically a single float32 in
+// the range 0.0-1.0 for faders and buttons.
+type Handler func(args []interface{})
+
+// Surface is a bidirectional OSC control surface: it listens for incoming
+// messages on listenAddr and dispatches them to the Handler registered for
+// their address, and it implements ctrl.Feedback by sending messages to
+// sendAddr so the surface can mirror the current state of a control (the OSC
+// equivalent of lighting a MIDI LED).
+type Surface struct {
+	server *goosc.Server
+	client *goosc.Client
+
+	mu       sync.RWMutex
+	handlers map[Address]Handler
+}
+
+// NewSurface creates a Surface that listens on listenAddr ("host:port") for
+// incoming OSC messages and sends feedback to sendAddr ("host:port").
+func NewSurface(listenAddr string, sendAddr string) (*Surface, error) {
+	host, port, err := splitHostPort(sendAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid osc send address %q: %w", sendAddr, err)
+	}
+
+	result := &Surface{
+		client:   goosc.NewClient(host, port),
+		handlers: make(map[Address]Handler),
+	}
+
+	dispatcher := goosc.NewStandardDispatcher()
+	err = dispatcher.AddMsgHandler("*", result.dispatch)
+	if err != nil {
+		return nil, fmt.Errorf("cannot install osc dispatcher: %w", err)
+	}
+	result.server = &goosc.Server{Addr: listenAddr, Dispatcher: dispatcher}
+
+	return result, nil
+}
+
+// ListenAndServe blocks, receiving OSC messages until the underlying
+// connection is closed. Run it in its own goroutine.
+func (s *Surface) ListenAndServe() error {
+	return s.server.ListenAndServe()
+}
+
+// Handle registers h to be called whenever a message for addr arrives.
+func (s *Surface) Handle(addr Address, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[addr] = h
+}
+
+func (s *Surface) dispatch(msg *goosc.Message) {
+	s.mu.RLock()
+	h, ok := s.handlers[Address(msg.Address)]
+	s.mu.RUnlock()
+	if !ok {
+		return
+	}
+	h(msg.Arguments)
+}
+
+// Set implements ctrl.Feedback by sending id's current on/off state back to
+// the surface as a 0.0/1.0 float, so TouchOSC/Open Stage Control style
+// clients can reflect the state of a control they don't own.
+func (s *Surface) Set(id ctrl.ControlID, on bool) {
+	addr, ok := id.(Address)
+	if !ok {
+		return
+	}
+
+	value := float32(0)
+	if on {
+		value = 1
+	}
+
+	msg := goosc.NewMessage(string(addr))
+	msg.Append(value)
+	err := s.client.Send(msg)
+	if err != nil {
+		log.Printf("Cannot send osc feedback to %s: %v", addr, err)
+	}
+}
+
+func splitHostPort(addr string) (string, int, error) {
+	host, portString, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(portString)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, port, nil
+}
@@ -0,0 +1,43 @@
+package osc
+
+import (
+	"testing"
+
+	goosc "github.com/hypebeast/go-osc/osc"
+)
+
+func TestSurfaceDispatchCallsTheRegisteredHandler(t *testing.T) {
+	surface, err := NewSurface("127.0.0.1:0", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewSurface: %v", err)
+	}
+
+	var received []interface{}
+	surface.Handle(Address("/trx/0/mute"), func(args []interface{}) {
+		received = args
+	})
+
+	surface.dispatch(goosc.NewMessage("/trx/0/mute", float32(1)))
+
+	if len(received) != 1 || received[0] != float32(1) {
+		t.Fatalf("expected the handler to receive [1], got %v", received)
+	}
+}
+
+func TestSurfaceDispatchIgnoresUnregisteredAddresses(t *testing.T) {
+	surface, err := NewSurface("127.0.0.1:0", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewSurface: %v", err)
+	}
+
+	called := false
+	surface.Handle(Address("/trx/0/mute"), func(args []interface{}) {
+		called = true
+	})
+
+	surface.dispatch(goosc.NewMessage("/trx/0/split"))
+
+	if called {
+		t.Fatal("expected the handler for a different address not to be called")
+	}
+}
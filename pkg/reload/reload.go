@@ -0,0 +1,150 @@
+// Package reload watches a bindings config file on disk and rebuilds the
+// ctrl bindings described in it whenever it changes, without restarting
+// midi2tci or dropping the running TCI client connection.
+package reload
+
+import (
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Set is whatever one generation of bindings looks like once built from a
+// config file. It must release everything it owns (goroutines, channels,
+// ...) when Close is called. ctrl.Set satisfies this.
+type Set interface {
+	Close()
+}
+
+// Builder parses the config file at path and constructs the next Set to run.
+// It returns an error, rather than panicking, so a config file with a typo
+// can be rejected without disturbing the currently running Set.
+type Builder func(path string) (Set, error)
+
+// Updater watches a config file and swaps in newly built Sets as the file
+// changes, debouncing bursts of filesystem events into a single rebuild.
+type Updater struct {
+	path     string
+	build    Builder
+	debounce time.Duration
+
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+
+	mu      sync.Mutex
+	current Set
+}
+
+// NewUpdater builds the initial Set from path and returns an Updater ready
+// to watch it for changes. debounce coalesces bursts of writes (editors
+// commonly emit several filesystem events per save) into a single rebuild.
+//
+// The watch is placed on path's parent directory rather than path itself:
+// most editors save "atomically" by writing a temp file and renaming it over
+// the original, which leaves a watch on the file itself following the old,
+// now-unlinked inode, so only the first edit after startup would ever be
+// seen. Watching the directory and filtering by base name survives that.
+func NewUpdater(path string, build Builder, debounce time.Duration) (*Updater, error) {
+	current, err := build(path)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	err = watcher.Add(filepath.Dir(path))
+	if err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	return &Updater{
+		path:     path,
+		build:    build,
+		debounce: debounce,
+		watcher:  watcher,
+		current:  current,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Current returns the Set that is currently active.
+func (u *Updater) Current() Set {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.current
+}
+
+// Run watches the config file until Close is called, rebuilding and
+// swapping in a new Set on every change that still parses. A change that
+// fails to parse is logged and the currently running Set is left in place.
+// Run blocks; call it in its own goroutine.
+func (u *Updater) Run() {
+	var timer *time.Timer
+	reload := make(chan struct{}, 1)
+
+	for {
+		select {
+		case event, ok := <-u.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Base(event.Name) != filepath.Base(u.path) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if timer == nil {
+				timer = time.AfterFunc(u.debounce, func() {
+					select {
+					case reload <- struct{}{}:
+					default:
+					}
+				})
+			} else {
+				timer.Reset(u.debounce)
+			}
+
+		case err, ok := <-u.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Error watching %s: %v", u.path, err)
+
+		case <-reload:
+			next, err := u.build(u.path)
+			if err != nil {
+				log.Printf("Cannot reload %s, keeping current bindings: %v", u.path, err)
+				continue
+			}
+			u.mu.Lock()
+			previous := u.current
+			u.current = next
+			u.mu.Unlock()
+			previous.Close()
+			log.Printf("Reloaded bindings from %s", u.path)
+
+		case <-u.done:
+			return
+		}
+	}
+}
+
+// Close stops watching the config file and releases the currently running
+// Set.
+func (u *Updater) Close() {
+	select {
+	case <-u.done:
+		return
+	default:
+		close(u.done)
+		u.watcher.Close()
+		u.Current().Close()
+	}
+}
@@ -0,0 +1,175 @@
+package reload
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeSet struct {
+	closed int32
+}
+
+func (s *fakeSet) Close() {
+	atomic.StoreInt32(&s.closed, 1)
+}
+
+func (s *fakeSet) isClosed() bool {
+	return atomic.LoadInt32(&s.closed) != 0
+}
+
+// fakeBuilder builds a fakeSet from whatever content is in path, failing
+// the build if the file contains "invalid".
+func fakeBuilder(builds *int32) Builder {
+	return func(path string) (Set, error) {
+		atomic.AddInt32(builds, 1)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if string(content) == "invalid" {
+			return nil, errors.New("invalid config")
+		}
+		return &fakeSet{}, nil
+	}
+}
+
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("condition not met before timeout")
+}
+
+func TestNewUpdaterBuildsTheInitialSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bindings.conf")
+	err := os.WriteFile(path, []byte("valid"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var builds int32
+	updater, err := NewUpdater(path, fakeBuilder(&builds), 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewUpdater: %v", err)
+	}
+	defer updater.Close()
+
+	if builds != 1 {
+		t.Fatalf("expected 1 build, got %d", builds)
+	}
+	if updater.Current() == nil {
+		t.Fatal("expected an initial Set")
+	}
+}
+
+func TestNewUpdaterFailsOnAnInvalidInitialConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bindings.conf")
+	err := os.WriteFile(path, []byte("invalid"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var builds int32
+	_, err = NewUpdater(path, fakeBuilder(&builds), 10*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected an error building the initial invalid config")
+	}
+}
+
+func TestUpdaterReloadsOnChangeAndClosesThePrevious(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bindings.conf")
+	err := os.WriteFile(path, []byte("valid"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var builds int32
+	updater, err := NewUpdater(path, fakeBuilder(&builds), 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewUpdater: %v", err)
+	}
+	defer updater.Close()
+
+	go updater.Run()
+
+	first := updater.Current().(*fakeSet)
+
+	err = os.WriteFile(path, []byte("valid again"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, time.Second, func() bool { return atomic.LoadInt32(&builds) >= 2 })
+	waitFor(t, time.Second, func() bool { return updater.Current() != Set(first) })
+
+	if !first.isClosed() {
+		t.Fatal("expected the previous Set to be closed after a reload")
+	}
+	if updater.Current().(*fakeSet).isClosed() {
+		t.Fatal("expected the new Set not to be closed")
+	}
+}
+
+func TestUpdaterKeepsTheCurrentSetOnAnInvalidReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bindings.conf")
+	err := os.WriteFile(path, []byte("valid"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var builds int32
+	updater, err := NewUpdater(path, fakeBuilder(&builds), 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewUpdater: %v", err)
+	}
+	defer updater.Close()
+
+	go updater.Run()
+
+	current := updater.Current()
+
+	err = os.WriteFile(path, []byte("invalid"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, time.Second, func() bool { return atomic.LoadInt32(&builds) >= 2 })
+	time.Sleep(50 * time.Millisecond)
+
+	if updater.Current() != current {
+		t.Fatal("expected the current Set to survive an invalid reload")
+	}
+	if current.(*fakeSet).isClosed() {
+		t.Fatal("expected the surviving Set not to be closed")
+	}
+}
+
+func TestUpdaterCloseReleasesTheCurrentSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bindings.conf")
+	err := os.WriteFile(path, []byte("valid"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var builds int32
+	updater, err := NewUpdater(path, fakeBuilder(&builds), 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewUpdater: %v", err)
+	}
+
+	current := updater.Current().(*fakeSet)
+	updater.Close()
+
+	if !current.isClosed() {
+		t.Fatal("expected Close to release the current Set")
+	}
+}